@@ -0,0 +1,46 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvmlreceiver
+
+import (
+	"go.opentelemetry.io/collector/receiver/scraperhelper"
+)
+
+// Config relies on the scraperhelper.ScraperControllerSettings to configure
+// the frequency with which the nvml receiver polls NVML for GPU telemetry.
+type Config struct {
+	scraperhelper.ScraperControllerSettings `mapstructure:",squash"`
+
+	// CollectPerMigDevice controls whether, on devices with MIG (Multi-Instance
+	// GPU) mode enabled, metrics are additionally reported for each MIG
+	// instance alongside the parent GPU. Defaults to true.
+	CollectPerMigDevice bool `mapstructure:"collect_per_mig_device"`
+
+	// CollectProcessMetrics controls whether per-process GPU memory and
+	// utilization metrics are collected. It defaults to false since a host
+	// running many GPU processes can make this high cardinality.
+	CollectProcessMetrics bool `mapstructure:"collect_process_metrics"`
+
+	// IncludeDevices, if non-empty, restricts collection to devices matching
+	// at least one of the given selectors. ExcludeDevices drops any device
+	// matching one of its selectors, applied after IncludeDevices. Each
+	// selector is matched as an integer device index, a device UUID, or a PCI
+	// bus ID (e.g. "0000:01:00.0", the lspci/nvidia-smi form; NVML's own
+	// 8-digit-domain form is also accepted), auto-detected from its form.
+	// These allow GPUs to be split across multiple collector instances or a
+	// known-bad device to be dropped from collection.
+	IncludeDevices []string `mapstructure:"include_devices"`
+	ExcludeDevices []string `mapstructure:"exclude_devices"`
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nvmlreceiver collects GPU telemetry from NVIDIA devices via NVML.
+package nvmlreceiver
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver/scraperhelper"
+)
+
+const (
+	typeStr   = "nvml"
+	stability = component.StabilityLevelBeta
+
+	defaultCollectionInterval = 60 * time.Second
+)
+
+// NewFactory creates a factory for the nvml receiver.
+func NewFactory() component.ReceiverFactory {
+	return component.NewReceiverFactory(
+		typeStr,
+		createDefaultConfig,
+		component.WithMetricsReceiver(createMetricsReceiver, stability),
+	)
+}
+
+func createDefaultConfig() config.Receiver {
+	return &Config{
+		ScraperControllerSettings: scraperhelper.NewDefaultScraperControllerSettings(typeStr),
+		CollectPerMigDevice:       true,
+	}
+}
+
+func createMetricsReceiver(
+	_ context.Context,
+	settings component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	consumer consumer.Metrics,
+) (component.MetricsReceiver, error) {
+	nvmlConfig := cfg.(*Config)
+	nvmlScraper := newNvmlScraper(nvmlConfig, settings)
+	scraper, err := scraperhelper.NewScraper(typeStr, nvmlScraper.scrape, scraperhelper.WithStart(nvmlScraper.start))
+	if err != nil {
+		return nil, err
+	}
+
+	return scraperhelper.NewScraperControllerReceiver(
+		&nvmlConfig.ScraperControllerSettings,
+		settings,
+		consumer,
+		scraperhelper.AddScraper(scraper),
+	)
+}
@@ -0,0 +1,780 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build gpu
+// +build gpu
+
+package nvmlreceiver
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// maxWarningsForFailedDeviceMetricQuery caps how many times the scraper will
+// log a warning for the same failing NVML query across scrape cycles, so a
+// GPU that lacks support for a given counter doesn't flood the logs forever.
+const maxWarningsForFailedDeviceMetricQuery = 10
+
+var (
+	nvmlInit                   = nvml.Init
+	nvmlShutdown               = nvml.Shutdown
+	nvmlDeviceGetCount         = nvml.DeviceGetCount
+	nvmlDeviceGetHandleByIndex = nvml.DeviceGetHandleByIndex
+	nvmlDeviceGetName          = func(device nvml.Device) (string, nvml.Return) { return device.GetName() }
+	nvmlDeviceGetUUID          = func(device nvml.Device) (string, nvml.Return) { return device.GetUUID() }
+	nvmlDeviceGetSamples       = func(
+		device nvml.Device, _type nvml.SamplingType, LastSeenTimeStamp uint64) (nvml.ValueType, []nvml.Sample, nvml.Return) {
+		return device.GetSamples(_type, LastSeenTimeStamp)
+	}
+	nvmlDeviceGetMemoryInfo  = func(device nvml.Device) (nvml.Memory, nvml.Return) { return device.GetMemoryInfo() }
+	nvmlDeviceGetTemperature = func(device nvml.Device, sensorType nvml.TemperatureSensors) (uint32, nvml.Return) {
+		return device.GetTemperature(sensorType)
+	}
+	nvmlDeviceGetPowerUsage         = func(device nvml.Device) (uint32, nvml.Return) { return device.GetPowerUsage() }
+	nvmlDeviceGetEnforcedPowerLimit = func(device nvml.Device) (uint32, nvml.Return) {
+		return device.GetEnforcedPowerLimit()
+	}
+	nvmlDeviceGetFanSpeed  = func(device nvml.Device) (uint32, nvml.Return) { return device.GetFanSpeed() }
+	nvmlDeviceGetClockInfo = func(device nvml.Device, clockType nvml.ClockType) (uint32, nvml.Return) {
+		return device.GetClockInfo(clockType)
+	}
+	nvmlDeviceGetPcieThroughput = func(device nvml.Device, counter nvml.PcieUtilCounter) (uint32, nvml.Return) {
+		return device.GetPcieThroughput(counter)
+	}
+	nvmlDeviceGetMemoryErrorCounter = func(
+		device nvml.Device, errorType nvml.MemoryErrorType, counterType nvml.EccCounterType, locationType nvml.MemoryLocation) (uint64, nvml.Return) {
+		return device.GetMemoryErrorCounter(errorType, counterType, locationType)
+	}
+	nvmlDeviceGetMigMode           = func(device nvml.Device) (int, int, nvml.Return) { return device.GetMigMode() }
+	nvmlDeviceGetMaxMigDeviceCount = func(device nvml.Device) (int, nvml.Return) {
+		return device.GetMaxMigDeviceCount()
+	}
+	nvmlDeviceGetMigDeviceHandleByIndex = func(device nvml.Device, index int) (nvml.Device, nvml.Return) {
+		return device.GetMigDeviceHandleByIndex(index)
+	}
+	nvmlDeviceGetGpuInstanceId           = func(device nvml.Device) (int, nvml.Return) { return device.GetGpuInstanceId() }
+	nvmlDeviceGetComputeInstanceId       = func(device nvml.Device) (int, nvml.Return) { return device.GetComputeInstanceId() }
+	nvmlDeviceGetComputeRunningProcesses = func(device nvml.Device) ([]nvml.ProcessInfo, nvml.Return) {
+		return device.GetComputeRunningProcesses()
+	}
+	nvmlDeviceGetGraphicsRunningProcesses = func(device nvml.Device) ([]nvml.ProcessInfo, nvml.Return) {
+		return device.GetGraphicsRunningProcesses()
+	}
+	nvmlDeviceGetProcessUtilization = func(device nvml.Device, lastSeenTimeStamp uint64) ([]nvml.ProcessUtilizationSample, nvml.Return) {
+		return device.GetProcessUtilization(lastSeenTimeStamp)
+	}
+	nvmlSystemGetProcessName = nvml.SystemGetProcessName
+	nvmlDeviceGetPciInfo     = func(device nvml.Device) (nvml.PciInfo, nvml.Return) { return device.GetPciInfo() }
+	nvmlDeviceGetNvLinkState = func(device nvml.Device, link int) (nvml.EnableState, nvml.Return) {
+		return device.GetNvLinkState(link)
+	}
+	nvmlDeviceGetNvLinkUtilizationCounter = func(device nvml.Device, link, counter int) (uint64, uint64, nvml.Return) {
+		return device.GetNvLinkUtilizationCounter(link, counter)
+	}
+	nvmlDeviceGetNvLinkErrorCounter = func(device nvml.Device, link int, counter nvml.NvLinkErrorCounter) (uint64, nvml.Return) {
+		return device.GetNvLinkErrorCounter(link, counter)
+	}
+	nvmlDeviceGetNvLinkRemotePciInfo = func(device nvml.Device, link int) (nvml.PciInfo, nvml.Return) {
+		return device.GetNvLinkRemotePciInfo(link)
+	}
+)
+
+var nvLinkErrorCounters = map[nvml.NvLinkErrorCounter]string{
+	nvml.NVLINK_ERROR_DL_CRC_DATA: "crc_data",
+	nvml.NVLINK_ERROR_DL_CRC_FLIT: "crc_flit",
+	nvml.NVLINK_ERROR_DL_REPLAY:   "replay",
+	nvml.NVLINK_ERROR_DL_RECOVERY: "recovery",
+}
+
+var clockTypes = map[nvml.ClockType]string{
+	nvml.CLOCK_SM:       "sm",
+	nvml.CLOCK_MEM:      "memory",
+	nvml.CLOCK_GRAPHICS: "graphics",
+	nvml.CLOCK_VIDEO:    "video",
+}
+
+var pcieDirections = map[nvml.PcieUtilCounter]string{
+	nvml.PCIE_UTIL_RX_BYTES: "rx",
+	nvml.PCIE_UTIL_TX_BYTES: "tx",
+}
+
+var eccErrorTypes = map[nvml.MemoryErrorType]string{
+	nvml.MEMORY_ERROR_TYPE_CORRECTED:   "corrected",
+	nvml.MEMORY_ERROR_TYPE_UNCORRECTED: "uncorrected",
+}
+
+// NVML only has one enum value for "device memory"/"dram" (they are the same
+// underlying counter), so the device/dram locations called out in the
+// feature request collapse to a single "device" attribute value here.
+var eccLocations = map[nvml.MemoryLocation]string{
+	nvml.MEMORY_LOCATION_DEVICE_MEMORY: "device",
+	nvml.MEMORY_LOCATION_REGISTER_FILE: "register_file",
+	nvml.MEMORY_LOCATION_L1_CACHE:      "l1",
+	nvml.MEMORY_LOCATION_L2_CACHE:      "l2",
+}
+
+// processNameCacheSize bounds how many resolved PID->process name mappings
+// the scraper keeps around, so a host churning through many short-lived GPU
+// processes doesn't grow the cache without bound.
+const processNameCacheSize = 256
+
+type nvmlScraper struct {
+	config   *Config
+	settings component.ReceiverCreateSettings
+
+	mu                  sync.Mutex
+	failedQueryWarnings map[string]int
+
+	processNames *processNameCache
+	deviceFilter deviceFilterFunc
+}
+
+func newNvmlScraper(cfg *Config, settings component.ReceiverCreateSettings) *nvmlScraper {
+	return &nvmlScraper{
+		config:              cfg,
+		settings:            settings,
+		failedQueryWarnings: map[string]int{},
+		processNames:        newProcessNameCache(processNameCacheSize),
+		deviceFilter:        newDeviceFilter(cfg),
+	}
+}
+
+func (s *nvmlScraper) start(_ context.Context, _ component.Host) error {
+	if ret := nvmlInit(); ret != nvml.SUCCESS {
+		return fmt.Errorf("failed to initialize NVML: %s", nvml.ErrorString(ret))
+	}
+	return nil
+}
+
+// warnOnce logs a warning for a failed device metric query, throttling
+// repeated warnings for the same query so a consistently unsupported metric
+// doesn't spam the logs on every scrape interval.
+func (s *nvmlScraper) warnOnce(key, msg string, ret nvml.Return) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failedQueryWarnings[key]++
+	if s.failedQueryWarnings[key] <= maxWarningsForFailedDeviceMetricQuery {
+		s.settings.Logger.Warn(msg, zap.String("query", key), zap.String("reason", nvml.ErrorString(ret)))
+	}
+}
+
+// processNameCache is a small, bounded LRU cache mapping a PID to its
+// resolved process name, so the scraper doesn't re-resolve a still-running
+// process's name on every scrape interval.
+type processNameCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[int]*list.Element
+}
+
+type processNameCacheEntry struct {
+	pid  int
+	name string
+}
+
+func newProcessNameCache(capacity int) *processNameCache {
+	return &processNameCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    map[int]*list.Element{},
+	}
+}
+
+func (c *processNameCache) get(pid int) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[pid]
+	if !ok {
+		return "", false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*processNameCacheEntry).name, true
+}
+
+func (c *processNameCache) put(pid int, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[pid]; ok {
+		el.Value.(*processNameCacheEntry).name = name
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	el := c.ll.PushFront(&processNameCacheEntry{pid: pid, name: name})
+	c.items[pid] = el
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*processNameCacheEntry).pid)
+	}
+}
+
+// reportingDevice is a unit of NVML metric collection: either a physical GPU
+// or, when MIG is enabled and CollectPerMigDevice is set, one of its MIG
+// instances. All recordXxx methods operate in terms of reportingDevice so
+// the same collection code runs for both.
+type reportingDevice struct {
+	handle    nvml.Device
+	gpuNumber int
+	model     string
+	uuid      string
+	isMig     bool
+	migUUID   string
+	giID      int
+	ciID      int
+}
+
+func setGpuAttributes(attrs pcommon.Map, rd reportingDevice) {
+	attrs.PutInt("gpu_number", int64(rd.gpuNumber))
+	attrs.PutString("model", rd.model)
+	attrs.PutString("uuid", rd.uuid)
+	if rd.isMig {
+		attrs.PutString("mig_uuid", rd.migUUID)
+		attrs.PutInt("gi_id", int64(rd.giID))
+		attrs.PutInt("ci_id", int64(rd.ciID))
+	}
+}
+
+// deviceFilterFunc reports whether a device identified by its index, UUID,
+// and PCI bus ID should be scraped.
+type deviceFilterFunc func(index int, uuid, busID string) bool
+
+// newDeviceFilter compiles a Config's IncludeDevices/ExcludeDevices lists
+// into a deviceFilterFunc. A device is scraped if it matches at least one
+// IncludeDevices selector (or IncludeDevices is empty) and matches no
+// ExcludeDevices selector.
+func newDeviceFilter(cfg *Config) deviceFilterFunc {
+	include := cfg.IncludeDevices
+	exclude := cfg.ExcludeDevices
+	return func(index int, uuid, busID string) bool {
+		if len(include) > 0 && !matchesAnyDeviceSelector(include, index, uuid, busID) {
+			return false
+		}
+		return !matchesAnyDeviceSelector(exclude, index, uuid, busID)
+	}
+}
+
+func matchesAnyDeviceSelector(selectors []string, index int, uuid, busID string) bool {
+	for _, selector := range selectors {
+		if matchesDeviceSelector(selector, index, uuid, busID) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesDeviceSelector matches a single IncludeDevices/ExcludeDevices entry,
+// auto-detecting whether it's an integer device index, a PCI bus ID (which
+// always contains a ":"), or a device UUID.
+func matchesDeviceSelector(selector string, index int, uuid, busID string) bool {
+	if n, err := strconv.Atoi(selector); err == nil {
+		return n == index
+	}
+	if strings.Contains(selector, ":") {
+		return strings.EqualFold(normalizePciBusID(selector), normalizePciBusID(busID))
+	}
+	return strings.EqualFold(selector, uuid)
+}
+
+// normalizePciBusID widens a PCI bus ID's domain segment to NVML's 8-hex-digit
+// form (e.g. "0000:01:00.0" -> "00000000:01:00.0") so that IDs in the short
+// form used by lspci/nvidia-smi compare equal to the ones NVML reports.
+func normalizePciBusID(id string) string {
+	domain, rest, ok := strings.Cut(id, ":")
+	if !ok {
+		return id
+	}
+	if len(domain) < 8 {
+		domain = strings.Repeat("0", 8-len(domain)) + domain
+	}
+	return domain + ":" + rest
+}
+
+func getOrCreateGaugeMetric(sm pmetric.ScopeMetrics, metricsByName map[string]pmetric.Metric, name string) pmetric.Metric {
+	if m, ok := metricsByName[name]; ok {
+		return m
+	}
+	m := sm.Metrics().AppendEmpty()
+	m.SetName(name)
+	m.SetEmptyGauge()
+	metricsByName[name] = m
+	return m
+}
+
+func (s *nvmlScraper) scrape(_ context.Context) (pmetric.Metrics, error) {
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	sm := rm.ScopeMetrics().AppendEmpty()
+	metricsByName := map[string]pmetric.Metric{}
+
+	count, ret := nvmlDeviceGetCount()
+	if ret != nvml.SUCCESS {
+		return metrics, fmt.Errorf("failed to query device count: %s", nvml.ErrorString(ret))
+	}
+
+	// busIDToUUID lets recordNvLink resolve an NVLink partner's UUID from its
+	// PCI bus ID. It's built in a first pass over every device before any
+	// metrics are recorded, since a link's partner can have a higher device
+	// index than the device reporting it.
+	busIDToUUID := map[string]string{}
+	var reportingDevices []reportingDevice
+
+	for i := 0; i < count; i++ {
+		device, ret := nvmlDeviceGetHandleByIndex(i)
+		if ret != nvml.SUCCESS {
+			s.warnOnce("DeviceGetHandleByIndex", "Unable to query device handle", ret)
+			continue
+		}
+
+		uuid, uuidRet := nvmlDeviceGetUUID(device)
+
+		var busID string
+		if pciInfo, ret := nvmlDeviceGetPciInfo(device); ret == nvml.SUCCESS {
+			busID = pciBusID(pciInfo)
+		}
+
+		if uuidRet == nvml.SUCCESS && busID != "" {
+			busIDToUUID[busID] = uuid
+		}
+
+		if !s.deviceFilter(i, uuid, busID) {
+			continue
+		}
+
+		if uuidRet != nvml.SUCCESS {
+			s.warnOnce("DeviceGetUUID", "Unable to query device uuid", uuidRet)
+		}
+
+		name, ret := nvmlDeviceGetName(device)
+		if ret != nvml.SUCCESS {
+			s.warnOnce("DeviceGetName", "Unable to query device name", ret)
+		}
+
+		reportingDevices = append(reportingDevices, reportingDevice{handle: device, gpuNumber: i, model: name, uuid: uuid})
+		reportingDevices = append(reportingDevices, s.migReportingDevices(device, i, name, uuid)...)
+	}
+
+	for _, rd := range reportingDevices {
+		s.recordUtilization(sm, metricsByName, rd)
+		s.recordMemoryInfo(sm, metricsByName, rd)
+		s.recordTemperature(sm, metricsByName, rd)
+		s.recordPower(sm, metricsByName, rd)
+		s.recordFanSpeed(sm, metricsByName, rd)
+		s.recordClocks(sm, metricsByName, rd)
+		s.recordPcieThroughput(sm, metricsByName, rd)
+		s.recordEccErrors(sm, metricsByName, rd)
+		s.recordNvLink(sm, metricsByName, rd, busIDToUUID)
+		if s.config.CollectProcessMetrics {
+			// Deliberately queried once per reportingDevice, including each
+			// MIG instance: NVML scopes a MIG device handle's process
+			// queries to the processes running on that instance, not the
+			// whole parent GPU, so this is how a PID gets attributed to the
+			// MIG instance it's actually using rather than double-counted
+			// against the parent.
+			s.recordProcessMemory(sm, metricsByName, rd)
+			s.recordProcessUtilization(sm, metricsByName, rd)
+		}
+	}
+
+	return metrics, nil
+}
+
+// migReportingDevices returns the MIG instances of device as reportingDevices
+// when MIG mode is enabled and per-MIG-device collection is turned on. It
+// returns nil for devices without MIG enabled, which is the common case.
+func (s *nvmlScraper) migReportingDevices(device nvml.Device, gpuNumber int, model, parentUUID string) []reportingDevice {
+	if !s.config.CollectPerMigDevice {
+		return nil
+	}
+
+	currentMode, _, ret := nvmlDeviceGetMigMode(device)
+	if ret != nvml.SUCCESS || currentMode != nvml.DEVICE_MIG_ENABLE {
+		return nil
+	}
+
+	maxMigDevices, ret := nvmlDeviceGetMaxMigDeviceCount(device)
+	if ret != nvml.SUCCESS {
+		s.warnOnce("DeviceGetMaxMigDeviceCount", "Unable to query MIG device count", ret)
+		return nil
+	}
+
+	var reportingDevices []reportingDevice
+	for j := 0; j < maxMigDevices; j++ {
+		migDevice, ret := nvmlDeviceGetMigDeviceHandleByIndex(device, j)
+		if ret != nvml.SUCCESS {
+			if ret != nvml.ERROR_NOT_FOUND {
+				s.warnOnce("DeviceGetMigDeviceHandleByIndex", "Unable to query MIG device handle", ret)
+			}
+			continue
+		}
+
+		migUUID, ret := nvmlDeviceGetUUID(migDevice)
+		if ret != nvml.SUCCESS {
+			s.warnOnce("DeviceGetUUID", "Unable to query MIG device uuid", ret)
+		}
+		giID, ret := nvmlDeviceGetGpuInstanceId(migDevice)
+		if ret != nvml.SUCCESS {
+			s.warnOnce("DeviceGetGpuInstanceId", "Unable to query MIG gpu instance id", ret)
+		}
+		ciID, ret := nvmlDeviceGetComputeInstanceId(migDevice)
+		if ret != nvml.SUCCESS {
+			s.warnOnce("DeviceGetComputeInstanceId", "Unable to query MIG compute instance id", ret)
+		}
+
+		reportingDevices = append(reportingDevices, reportingDevice{
+			handle:    migDevice,
+			gpuNumber: gpuNumber,
+			model:     model,
+			uuid:      parentUUID,
+			isMig:     true,
+			migUUID:   migUUID,
+			giID:      giID,
+			ciID:      ciID,
+		})
+	}
+	return reportingDevices
+}
+
+func (s *nvmlScraper) recordUtilization(sm pmetric.ScopeMetrics, metricsByName map[string]pmetric.Metric, rd reportingDevice) {
+	valueType, samples, ret := nvmlDeviceGetSamples(rd.handle, nvml.GPU_UTILIZATION_SAMPLES, 0)
+	if ret != nvml.SUCCESS {
+		s.warnOnce("nvml.gpu.utilization", "Unable to query nvml.gpu.utilization", ret)
+		return
+	}
+	if len(samples) == 0 {
+		return
+	}
+
+	m := getOrCreateGaugeMetric(sm, metricsByName, "nvml.gpu.utilization")
+	dp := m.Gauge().DataPoints().AppendEmpty()
+	dp.SetIntVal(sampleValueAsInt64(valueType, samples[len(samples)-1].SampleValue))
+	setGpuAttributes(dp.Attributes(), rd)
+}
+
+// sampleValueAsInt64 decodes the tagged union nvmlValueType_t that
+// DeviceGetSamples returns its results as, since the go-nvml binding hands it
+// back as raw bytes rather than a typed value.
+func sampleValueAsInt64(valueType nvml.ValueType, raw [8]byte) int64 {
+	switch valueType {
+	case nvml.VALUE_TYPE_DOUBLE:
+		return int64(math.Float64frombits(binary.LittleEndian.Uint64(raw[:])))
+	case nvml.VALUE_TYPE_UNSIGNED_INT, nvml.VALUE_TYPE_SIGNED_INT, nvml.VALUE_TYPE_UNSIGNED_SHORT:
+		return int64(binary.LittleEndian.Uint32(raw[:4]))
+	default:
+		return int64(binary.LittleEndian.Uint64(raw[:]))
+	}
+}
+
+func (s *nvmlScraper) recordMemoryInfo(sm pmetric.ScopeMetrics, metricsByName map[string]pmetric.Metric, rd reportingDevice) {
+	memInfo, ret := nvmlDeviceGetMemoryInfo(rd.handle)
+	if ret != nvml.SUCCESS {
+		s.warnOnce("nvml.gpu.memory.bytes_used", "Unable to query nvml.gpu.memory.bytes_used", ret)
+		return
+	}
+
+	m := getOrCreateGaugeMetric(sm, metricsByName, "nvml.gpu.memory.bytes_used")
+
+	used := m.Gauge().DataPoints().AppendEmpty()
+	used.SetIntVal(int64(memInfo.Used))
+	setGpuAttributes(used.Attributes(), rd)
+	used.Attributes().PutString("memory_state", "used")
+
+	free := m.Gauge().DataPoints().AppendEmpty()
+	free.SetIntVal(int64(memInfo.Free))
+	setGpuAttributes(free.Attributes(), rd)
+	free.Attributes().PutString("memory_state", "free")
+}
+
+// recordTemperature reports the GPU die temperature. NVML's TemperatureSensors
+// enum only defines a GPU sensor today (there's no memory-sensor counterpart
+// to read), so that's the only reading emitted here.
+func (s *nvmlScraper) recordTemperature(sm pmetric.ScopeMetrics, metricsByName map[string]pmetric.Metric, rd reportingDevice) {
+	temp, ret := nvmlDeviceGetTemperature(rd.handle, nvml.TEMPERATURE_GPU)
+	if ret != nvml.SUCCESS {
+		s.warnOnce("nvml.gpu.temperature", "Unable to query nvml.gpu.temperature", ret)
+		return
+	}
+
+	m := getOrCreateGaugeMetric(sm, metricsByName, "nvml.gpu.temperature")
+	dp := m.Gauge().DataPoints().AppendEmpty()
+	dp.SetIntVal(int64(temp))
+	setGpuAttributes(dp.Attributes(), rd)
+	dp.Attributes().PutString("sensor", "gpu")
+}
+
+func (s *nvmlScraper) recordPower(sm pmetric.ScopeMetrics, metricsByName map[string]pmetric.Metric, rd reportingDevice) {
+	usage, ret := nvmlDeviceGetPowerUsage(rd.handle)
+	if ret != nvml.SUCCESS {
+		s.warnOnce("nvml.gpu.power.usage_milliwatts", "Unable to query nvml.gpu.power.usage_milliwatts", ret)
+	} else {
+		m := getOrCreateGaugeMetric(sm, metricsByName, "nvml.gpu.power.usage_milliwatts")
+		dp := m.Gauge().DataPoints().AppendEmpty()
+		dp.SetIntVal(int64(usage))
+		setGpuAttributes(dp.Attributes(), rd)
+	}
+
+	limit, ret := nvmlDeviceGetEnforcedPowerLimit(rd.handle)
+	if ret != nvml.SUCCESS {
+		s.warnOnce("nvml.gpu.power.limit", "Unable to query nvml.gpu.power.limit", ret)
+		return
+	}
+	m := getOrCreateGaugeMetric(sm, metricsByName, "nvml.gpu.power.limit")
+	dp := m.Gauge().DataPoints().AppendEmpty()
+	dp.SetIntVal(int64(limit))
+	setGpuAttributes(dp.Attributes(), rd)
+}
+
+func (s *nvmlScraper) recordFanSpeed(sm pmetric.ScopeMetrics, metricsByName map[string]pmetric.Metric, rd reportingDevice) {
+	speed, ret := nvmlDeviceGetFanSpeed(rd.handle)
+	if ret != nvml.SUCCESS {
+		s.warnOnce("nvml.gpu.fan.speed", "Unable to query nvml.gpu.fan.speed", ret)
+		return
+	}
+
+	m := getOrCreateGaugeMetric(sm, metricsByName, "nvml.gpu.fan.speed")
+	dp := m.Gauge().DataPoints().AppendEmpty()
+	dp.SetIntVal(int64(speed))
+	setGpuAttributes(dp.Attributes(), rd)
+}
+
+func (s *nvmlScraper) recordClocks(sm pmetric.ScopeMetrics, metricsByName map[string]pmetric.Metric, rd reportingDevice) {
+	for clockType, clockTypeAttr := range clockTypes {
+		freq, ret := nvmlDeviceGetClockInfo(rd.handle, clockType)
+		if ret != nvml.SUCCESS {
+			s.warnOnce("nvml.gpu.clock.frequency", "Unable to query nvml.gpu.clock.frequency", ret)
+			continue
+		}
+
+		m := getOrCreateGaugeMetric(sm, metricsByName, "nvml.gpu.clock.frequency")
+		dp := m.Gauge().DataPoints().AppendEmpty()
+		dp.SetIntVal(int64(freq))
+		setGpuAttributes(dp.Attributes(), rd)
+		dp.Attributes().PutString("clock_type", clockTypeAttr)
+	}
+}
+
+func (s *nvmlScraper) recordPcieThroughput(sm pmetric.ScopeMetrics, metricsByName map[string]pmetric.Metric, rd reportingDevice) {
+	for counter, direction := range pcieDirections {
+		throughput, ret := nvmlDeviceGetPcieThroughput(rd.handle, counter)
+		if ret != nvml.SUCCESS {
+			s.warnOnce("nvml.gpu.pcie.throughput", "Unable to query nvml.gpu.pcie.throughput", ret)
+			continue
+		}
+
+		m := getOrCreateGaugeMetric(sm, metricsByName, "nvml.gpu.pcie.throughput")
+		dp := m.Gauge().DataPoints().AppendEmpty()
+		dp.SetIntVal(int64(throughput))
+		setGpuAttributes(dp.Attributes(), rd)
+		dp.Attributes().PutString("direction", direction)
+	}
+}
+
+func (s *nvmlScraper) recordEccErrors(sm pmetric.ScopeMetrics, metricsByName map[string]pmetric.Metric, rd reportingDevice) {
+	for errorType, errorTypeAttr := range eccErrorTypes {
+		for location, locationAttr := range eccLocations {
+			count, ret := nvmlDeviceGetMemoryErrorCounter(rd.handle, errorType, nvml.AGGREGATE_ECC, location)
+			if ret != nvml.SUCCESS {
+				s.warnOnce("nvml.gpu.ecc.errors", "Unable to query nvml.gpu.ecc.errors", ret)
+				continue
+			}
+
+			m := getOrCreateGaugeMetric(sm, metricsByName, "nvml.gpu.ecc.errors")
+			dp := m.Gauge().DataPoints().AppendEmpty()
+			dp.SetIntVal(int64(count))
+			setGpuAttributes(dp.Attributes(), rd)
+			dp.Attributes().PutString("type", errorTypeAttr)
+			dp.Attributes().PutString("location", locationAttr)
+		}
+	}
+}
+
+// resolveProcessName resolves pid to a process name via NVML, going through
+// processNames first since SystemGetProcessName is a syscall-backed query we
+// don't want to repeat for every scrape interval a process stays alive.
+func (s *nvmlScraper) resolveProcessName(pid uint32) string {
+	if name, ok := s.processNames.get(int(pid)); ok {
+		return name
+	}
+
+	name, ret := nvmlSystemGetProcessName(int(pid))
+	if ret != nvml.SUCCESS {
+		s.warnOnce("SystemGetProcessName", "Unable to resolve process name", ret)
+		return ""
+	}
+	s.processNames.put(int(pid), name)
+	return name
+}
+
+func (s *nvmlScraper) recordProcessMemory(sm pmetric.ScopeMetrics, metricsByName map[string]pmetric.Metric, rd reportingDevice) {
+	s.recordRunningProcesses(sm, metricsByName, rd, nvmlDeviceGetComputeRunningProcesses, "compute")
+	s.recordRunningProcesses(sm, metricsByName, rd, nvmlDeviceGetGraphicsRunningProcesses, "graphics")
+}
+
+func (s *nvmlScraper) recordRunningProcesses(
+	sm pmetric.ScopeMetrics, metricsByName map[string]pmetric.Metric, rd reportingDevice,
+	query func(nvml.Device) ([]nvml.ProcessInfo, nvml.Return), processType string) {
+	infos, ret := query(rd.handle)
+	if ret != nvml.SUCCESS {
+		s.warnOnce("nvml.gpu.process.memory.bytes_used."+processType, "Unable to query nvml.gpu.process.memory.bytes_used", ret)
+		return
+	}
+
+	m := getOrCreateGaugeMetric(sm, metricsByName, "nvml.gpu.process.memory.bytes_used")
+	for _, info := range infos {
+		dp := m.Gauge().DataPoints().AppendEmpty()
+		dp.SetIntVal(int64(info.UsedGpuMemory))
+		setGpuAttributes(dp.Attributes(), rd)
+		dp.Attributes().PutInt("pid", int64(info.Pid))
+		dp.Attributes().PutString("process_name", s.resolveProcessName(info.Pid))
+		dp.Attributes().PutString("process_type", processType)
+	}
+}
+
+// recordProcessUtilization reports per-process SM/memory/encoder/decoder
+// utilization. Unlike recordRunningProcesses, there's no process_type
+// attribute here: DeviceGetProcessUtilization's sample doesn't say whether a
+// PID is a compute or graphics client, so there's nothing to report it from.
+func (s *nvmlScraper) recordProcessUtilization(sm pmetric.ScopeMetrics, metricsByName map[string]pmetric.Metric, rd reportingDevice) {
+	samples, ret := nvmlDeviceGetProcessUtilization(rd.handle, 0)
+	if ret != nvml.SUCCESS {
+		s.warnOnce("nvml.gpu.process.utilization", "Unable to query nvml.gpu.process.utilization", ret)
+		return
+	}
+
+	m := getOrCreateGaugeMetric(sm, metricsByName, "nvml.gpu.process.utilization")
+	for _, sample := range samples {
+		processName := s.resolveProcessName(sample.Pid)
+		components := []struct {
+			name  string
+			value uint32
+		}{
+			{"sm", sample.SmUtil},
+			{"memory", sample.MemUtil},
+			{"encoder", sample.EncUtil},
+			{"decoder", sample.DecUtil},
+		}
+		for _, c := range components {
+			dp := m.Gauge().DataPoints().AppendEmpty()
+			dp.SetIntVal(int64(c.value))
+			setGpuAttributes(dp.Attributes(), rd)
+			dp.Attributes().PutInt("pid", int64(sample.Pid))
+			dp.Attributes().PutString("process_name", processName)
+			dp.Attributes().PutString("component", c.name)
+		}
+	}
+}
+
+// pciBusID decodes the NUL-terminated C string NVML returns for a PciInfo's
+// BusId field into a Go string.
+func pciBusID(info nvml.PciInfo) string {
+	b := make([]byte, 0, len(info.BusId))
+	for _, c := range info.BusId {
+		if c == 0 {
+			break
+		}
+		b = append(b, byte(c))
+	}
+	return string(b)
+}
+
+// recordNvLink reports throughput via DeviceGetNvLinkUtilizationCounter,
+// which NVML zeroes until SetNvLinkUtilizationControl has configured the
+// counter; on hosts that never call it, nvml.gpu.nvlink.throughput reads as
+// 0 rather than failing outright. DeviceGetFieldValues with the
+// NVLINK_THROUGHPUT_DATA_{TX,RX} field IDs doesn't have that requirement and
+// would be a more reliable source if this turns out to matter in practice.
+func (s *nvmlScraper) recordNvLink(sm pmetric.ScopeMetrics, metricsByName map[string]pmetric.Metric, rd reportingDevice, busIDToUUID map[string]string) {
+	for link := 0; link < nvml.NVLINK_MAX_LINKS; link++ {
+		state, ret := nvmlDeviceGetNvLinkState(rd.handle, link)
+		if ret != nvml.SUCCESS || state != nvml.FEATURE_ENABLED {
+			continue
+		}
+
+		remoteUUID := s.resolveNvLinkRemoteUUID(rd.handle, link, busIDToUUID)
+
+		rxBytes, txBytes, ret := nvmlDeviceGetNvLinkUtilizationCounter(rd.handle, link, 0)
+		if ret != nvml.SUCCESS {
+			s.warnOnce("nvml.gpu.nvlink.throughput", "Unable to query nvml.gpu.nvlink.throughput", ret)
+		} else {
+			m := getOrCreateGaugeMetric(sm, metricsByName, "nvml.gpu.nvlink.throughput")
+
+			rx := m.Gauge().DataPoints().AppendEmpty()
+			rx.SetIntVal(int64(rxBytes))
+			setGpuAttributes(rx.Attributes(), rd)
+			rx.Attributes().PutInt("link", int64(link))
+			rx.Attributes().PutString("direction", "rx")
+			if remoteUUID != "" {
+				rx.Attributes().PutString("remote_uuid", remoteUUID)
+			}
+
+			tx := m.Gauge().DataPoints().AppendEmpty()
+			tx.SetIntVal(int64(txBytes))
+			setGpuAttributes(tx.Attributes(), rd)
+			tx.Attributes().PutInt("link", int64(link))
+			tx.Attributes().PutString("direction", "tx")
+			if remoteUUID != "" {
+				tx.Attributes().PutString("remote_uuid", remoteUUID)
+			}
+		}
+
+		for errorCounter, errorTypeAttr := range nvLinkErrorCounters {
+			count, ret := nvmlDeviceGetNvLinkErrorCounter(rd.handle, link, errorCounter)
+			if ret != nvml.SUCCESS {
+				s.warnOnce("nvml.gpu.nvlink.errors", "Unable to query nvml.gpu.nvlink.errors", ret)
+				continue
+			}
+
+			m := getOrCreateGaugeMetric(sm, metricsByName, "nvml.gpu.nvlink.errors")
+			dp := m.Gauge().DataPoints().AppendEmpty()
+			dp.SetIntVal(int64(count))
+			setGpuAttributes(dp.Attributes(), rd)
+			dp.Attributes().PutInt("link", int64(link))
+			dp.Attributes().PutString("error_type", errorTypeAttr)
+			if remoteUUID != "" {
+				dp.Attributes().PutString("remote_uuid", remoteUUID)
+			}
+		}
+	}
+}
+
+// resolveNvLinkRemoteUUID looks up the UUID of the device on the other end of
+// an NVLink connection by matching its PCI bus ID against busIDToUUID, since
+// NVML only reports the remote endpoint's PCI info directly. busIDToUUID is
+// built once per scrape from the devices already enumerated, rather than
+// re-enumerating devices for every link.
+func (s *nvmlScraper) resolveNvLinkRemoteUUID(device nvml.Device, link int, busIDToUUID map[string]string) string {
+	remotePciInfo, ret := nvmlDeviceGetNvLinkRemotePciInfo(device, link)
+	if ret != nvml.SUCCESS {
+		s.warnOnce("DeviceGetNvLinkRemotePciInfo", "Unable to query nvlink remote pci info", ret)
+		return ""
+	}
+	return busIDToUUID[pciBusID(remotePciInfo)]
+}
@@ -19,6 +19,7 @@ package nvmlreceiver
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -40,7 +41,20 @@ func TestScrapeWithGpuPresent(t *testing.T) {
 	require.NoError(t, err)
 
 	metrics, err := scraper.scrape(context.Background())
-	validateScraperResult(t, metrics, []string{"nvml.gpu.utilization", "nvml.gpu.memory.bytes_used"})
+	require.NoError(t, err)
+	// fan.speed and pcie.throughput are omitted by NVML with ERROR_NOT_SUPPORTED
+	// on passively-cooled datacenter GPUs, and the nvlink families are only
+	// emitted on hosts with an enabled NVLink, so all three are optional
+	// rather than required on whatever hardware this test runs on.
+	validateScraperResult(t, metrics, []string{
+		"nvml.gpu.utilization",
+		"nvml.gpu.memory.bytes_used",
+		"nvml.gpu.temperature",
+		"nvml.gpu.power.usage_milliwatts",
+		"nvml.gpu.power.limit",
+		"nvml.gpu.clock.frequency",
+		"nvml.gpu.ecc.errors",
+	}, "nvml.gpu.fan.speed", "nvml.gpu.pcie.throughput", "nvml.gpu.nvlink.throughput", "nvml.gpu.nvlink.errors")
 }
 
 func TestScrapeOnGpuUtilizationUnsupported(t *testing.T) {
@@ -58,7 +72,17 @@ func TestScrapeOnGpuUtilizationUnsupported(t *testing.T) {
 	require.NoError(t, err)
 
 	metrics, err := scraper.scrape(context.Background())
-	validateScraperResult(t, metrics, []string{"nvml.gpu.memory.bytes_used"})
+	require.NoError(t, err)
+	validateScraperResult(t, metrics, []string{
+		"nvml.gpu.memory.bytes_used",
+		"nvml.gpu.temperature",
+		"nvml.gpu.power.usage_milliwatts",
+		"nvml.gpu.power.limit",
+		"nvml.gpu.fan.speed",
+		"nvml.gpu.clock.frequency",
+		"nvml.gpu.pcie.throughput",
+		"nvml.gpu.ecc.errors",
+	})
 }
 
 func TestScrapeOnGpuMemoryInfoUnsupported(t *testing.T) {
@@ -75,57 +99,818 @@ func TestScrapeOnGpuMemoryInfoUnsupported(t *testing.T) {
 	require.NoError(t, err)
 
 	metrics, err := scraper.scrape(context.Background())
-	validateScraperResult(t, metrics, []string{"nvml.gpu.utilization"})
+	require.NoError(t, err)
+	validateScraperResult(t, metrics, []string{
+		"nvml.gpu.utilization",
+		"nvml.gpu.temperature",
+		"nvml.gpu.power.usage_milliwatts",
+		"nvml.gpu.power.limit",
+		"nvml.gpu.fan.speed",
+		"nvml.gpu.clock.frequency",
+		"nvml.gpu.pcie.throughput",
+		"nvml.gpu.ecc.errors",
+	})
 }
 
-func TestScrapeEmitsWarningsUptoThreshold(t *testing.T) {
-	realNvmlGetSamples := nvmlDeviceGetSamples
-	defer func() { nvmlDeviceGetSamples = realNvmlGetSamples }()
+// TestScrapeWithMigEnabled verifies that, when a device reports MIG mode
+// enabled, the scraper additionally emits datapoints for each MIG instance
+// tagged with mig_uuid/gi_id/ci_id, alongside the parent GPU's datapoints.
+func TestScrapeWithMigEnabled(t *testing.T) {
+	realMigMode := nvmlDeviceGetMigMode
+	defer func() { nvmlDeviceGetMigMode = realMigMode }()
+	nvmlDeviceGetMigMode = func(device nvml.Device) (int, int, nvml.Return) {
+		return nvml.DEVICE_MIG_ENABLE, nvml.DEVICE_MIG_ENABLE, nvml.SUCCESS
+	}
+
+	realMaxMigDeviceCount := nvmlDeviceGetMaxMigDeviceCount
+	defer func() { nvmlDeviceGetMaxMigDeviceCount = realMaxMigDeviceCount }()
+	nvmlDeviceGetMaxMigDeviceCount = func(device nvml.Device) (int, nvml.Return) {
+		return 1, nvml.SUCCESS
+	}
+
+	realMigDeviceHandleByIndex := nvmlDeviceGetMigDeviceHandleByIndex
+	defer func() { nvmlDeviceGetMigDeviceHandleByIndex = realMigDeviceHandleByIndex }()
+	nvmlDeviceGetMigDeviceHandleByIndex = func(device nvml.Device, index int) (nvml.Device, nvml.Return) {
+		if index == 0 {
+			return device, nvml.SUCCESS
+		}
+		return nil, nvml.ERROR_NOT_FOUND
+	}
+
+	scraper := newNvmlScraper(createDefaultConfig().(*Config), componenttest.NewNopReceiverCreateSettings())
+	require.NotNil(t, scraper)
+
+	err := scraper.start(context.Background(), componenttest.NewNopHost())
+	require.NoError(t, err)
+
+	metrics, err := scraper.scrape(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, hasDatapointWithAttribute(metrics, "mig_uuid"), "expected a datapoint reported for the MIG instance")
+}
+
+// TestScrapeWithCollectPerMigDeviceDisabled verifies that per-MIG-instance
+// emission can be turned off even when the device reports MIG mode enabled.
+func TestScrapeWithCollectPerMigDeviceDisabled(t *testing.T) {
+	realMigMode := nvmlDeviceGetMigMode
+	defer func() { nvmlDeviceGetMigMode = realMigMode }()
+	nvmlDeviceGetMigMode = func(device nvml.Device) (int, int, nvml.Return) {
+		return nvml.DEVICE_MIG_ENABLE, nvml.DEVICE_MIG_ENABLE, nvml.SUCCESS
+	}
+
+	realMaxMigDeviceCount := nvmlDeviceGetMaxMigDeviceCount
+	defer func() { nvmlDeviceGetMaxMigDeviceCount = realMaxMigDeviceCount }()
+	nvmlDeviceGetMaxMigDeviceCount = func(device nvml.Device) (int, nvml.Return) {
+		return 1, nvml.SUCCESS
+	}
+
+	realMigDeviceHandleByIndex := nvmlDeviceGetMigDeviceHandleByIndex
+	defer func() { nvmlDeviceGetMigDeviceHandleByIndex = realMigDeviceHandleByIndex }()
+	nvmlDeviceGetMigDeviceHandleByIndex = func(device nvml.Device, index int) (nvml.Device, nvml.Return) {
+		if index == 0 {
+			return device, nvml.SUCCESS
+		}
+		return nil, nvml.ERROR_NOT_FOUND
+	}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectPerMigDevice = false
+	scraper := newNvmlScraper(cfg, componenttest.NewNopReceiverCreateSettings())
+	require.NotNil(t, scraper)
+
+	err := scraper.start(context.Background(), componenttest.NewNopHost())
+	require.NoError(t, err)
+
+	metrics, err := scraper.scrape(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, hasDatapointWithAttribute(metrics, "mig_uuid"), "expected no MIG instance datapoints when CollectPerMigDevice is false")
+}
+
+func hasDatapointWithAttribute(metrics pmetric.Metrics, attribute string) bool {
+	ilms := metrics.ResourceMetrics().At(0).ScopeMetrics()
+	ms := ilms.At(0).Metrics()
+	for i := 0; i < ms.Len(); i++ {
+		dps := ms.At(i).Gauge().DataPoints()
+		for j := 0; j < dps.Len(); j++ {
+			if _, ok := dps.At(j).Attributes().Get(attribute); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TestScrapeWithProcessMetricsEnabled verifies that, when CollectProcessMetrics
+// is set, per-process memory and utilization datapoints are emitted with the
+// pid/process_name/process_type attributes expected by consumers.
+func TestScrapeWithProcessMetricsEnabled(t *testing.T) {
+	realComputeRunningProcesses := nvmlDeviceGetComputeRunningProcesses
+	defer func() { nvmlDeviceGetComputeRunningProcesses = realComputeRunningProcesses }()
+	nvmlDeviceGetComputeRunningProcesses = func(device nvml.Device) ([]nvml.ProcessInfo, nvml.Return) {
+		return []nvml.ProcessInfo{{Pid: 1234, UsedGpuMemory: 1024}}, nvml.SUCCESS
+	}
+
+	realGraphicsRunningProcesses := nvmlDeviceGetGraphicsRunningProcesses
+	defer func() { nvmlDeviceGetGraphicsRunningProcesses = realGraphicsRunningProcesses }()
+	nvmlDeviceGetGraphicsRunningProcesses = func(device nvml.Device) ([]nvml.ProcessInfo, nvml.Return) {
+		return []nvml.ProcessInfo{{Pid: 5678, UsedGpuMemory: 2048}}, nvml.SUCCESS
+	}
+
+	realProcessUtilization := nvmlDeviceGetProcessUtilization
+	defer func() { nvmlDeviceGetProcessUtilization = realProcessUtilization }()
+	nvmlDeviceGetProcessUtilization = func(device nvml.Device, lastSeenTimeStamp uint64) ([]nvml.ProcessUtilizationSample, nvml.Return) {
+		return []nvml.ProcessUtilizationSample{{Pid: 1234, SmUtil: 10, MemUtil: 20, EncUtil: 30, DecUtil: 40}}, nvml.SUCCESS
+	}
+
+	realSystemGetProcessName := nvmlSystemGetProcessName
+	defer func() { nvmlSystemGetProcessName = realSystemGetProcessName }()
+	nvmlSystemGetProcessName = func(pid int) (string, nvml.Return) {
+		return fmt.Sprintf("proc-%d", pid), nvml.SUCCESS
+	}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectProcessMetrics = true
+	scraper := newNvmlScraper(cfg, componenttest.NewNopReceiverCreateSettings())
+	require.NotNil(t, scraper)
+
+	err := scraper.start(context.Background(), componenttest.NewNopHost())
+	require.NoError(t, err)
+
+	metrics, err := scraper.scrape(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, hasDatapointWithAttribute(metrics, "process_name"), "expected a process memory datapoint")
+	assert.True(t, hasDatapointWithAttribute(metrics, "component"), "expected a process utilization datapoint")
+}
+
+// TestScrapeWithProcessMetricsDisabled verifies that, by default, no
+// per-process metrics are collected even if the underlying queries succeed.
+func TestScrapeWithProcessMetricsDisabled(t *testing.T) {
+	realComputeRunningProcesses := nvmlDeviceGetComputeRunningProcesses
+	defer func() { nvmlDeviceGetComputeRunningProcesses = realComputeRunningProcesses }()
+	nvmlDeviceGetComputeRunningProcesses = func(device nvml.Device) ([]nvml.ProcessInfo, nvml.Return) {
+		return []nvml.ProcessInfo{{Pid: 1234, UsedGpuMemory: 1024}}, nvml.SUCCESS
+	}
+
+	scraper := newNvmlScraper(createDefaultConfig().(*Config), componenttest.NewNopReceiverCreateSettings())
+	require.NotNil(t, scraper)
+
+	err := scraper.start(context.Background(), componenttest.NewNopHost())
+	require.NoError(t, err)
+
+	metrics, err := scraper.scrape(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, hasDatapointWithAttribute(metrics, "process_name"), "expected no process datapoints when CollectProcessMetrics is false")
+}
+
+// fakeDevice gives tests distinct nvml.Device values for a synthetic
+// multi-device set; nvml.Device is an interface, so embedding a nil one here
+// satisfies it while index lets stubs tell devices apart.
+type fakeDevice struct {
+	nvml.Device
+	index int
+}
+
+// TestScrapeWithIncludeExcludeDevices verifies that IncludeDevices and
+// ExcludeDevices selectors -- matched by index, UUID, or PCI bus ID -- are
+// applied against a multi-device set so that only the devices surviving both
+// filters are scraped.
+func TestScrapeWithIncludeExcludeDevices(t *testing.T) {
+	realCount := nvmlDeviceGetCount
+	defer func() { nvmlDeviceGetCount = realCount }()
+	nvmlDeviceGetCount = func() (int, nvml.Return) { return 3, nvml.SUCCESS }
+
+	realHandleByIndex := nvmlDeviceGetHandleByIndex
+	defer func() { nvmlDeviceGetHandleByIndex = realHandleByIndex }()
+	nvmlDeviceGetHandleByIndex = func(index int) (nvml.Device, nvml.Return) {
+		return &fakeDevice{index: index}, nvml.SUCCESS
+	}
+
+	realUUID := nvmlDeviceGetUUID
+	defer func() { nvmlDeviceGetUUID = realUUID }()
+	nvmlDeviceGetUUID = func(device nvml.Device) (string, nvml.Return) {
+		return fmt.Sprintf("GPU-%d", device.(*fakeDevice).index), nvml.SUCCESS
+	}
+
+	realPciInfo := nvmlDeviceGetPciInfo
+	defer func() { nvmlDeviceGetPciInfo = realPciInfo }()
+	nvmlDeviceGetPciInfo = func(device nvml.Device) (nvml.PciInfo, nvml.Return) {
+		// NVML fills BusId with the real 8-hex-digit domain form
+		// (NVML_DEVICE_PCI_BUS_ID_FMT), not the 4-digit lspci/nvidia-smi form
+		// used in selectors, so the stub must reproduce that to exercise the
+		// normalization matchesDeviceSelector relies on.
+		busID := fmt.Sprintf("00000000:0%d:00.0", device.(*fakeDevice).index)
+		return nvml.PciInfo{BusId: toBusIdArray(busID)}, nvml.SUCCESS
+	}
+
+	// fakeDevice embeds a nil nvml.Device, so every query the scrape path
+	// issues against the device that survives filtering must be stubbed --
+	// otherwise it falls through to the nil embedded interface and panics.
+	realName := nvmlDeviceGetName
+	defer func() { nvmlDeviceGetName = realName }()
+	nvmlDeviceGetName = func(device nvml.Device) (string, nvml.Return) { return "", nvml.SUCCESS }
+
+	realMigMode := nvmlDeviceGetMigMode
+	defer func() { nvmlDeviceGetMigMode = realMigMode }()
+	nvmlDeviceGetMigMode = func(device nvml.Device) (int, int, nvml.Return) {
+		return nvml.DEVICE_MIG_DISABLE, nvml.DEVICE_MIG_DISABLE, nvml.SUCCESS
+	}
+
+	realSamples := nvmlDeviceGetSamples
+	defer func() { nvmlDeviceGetSamples = realSamples }()
 	nvmlDeviceGetSamples = func(
-		device nvml.Device, _type nvml.SamplingType, LastSeenTimeStamp uint64) (nvml.ValueType, []nvml.Sample, nvml.Return) {
-		return nvml.VALUE_TYPE_SIGNED_LONG_LONG, nil, nvml.ERROR_NOT_SUPPORTED
+		device nvml.Device, _type nvml.SamplingType, lastSeenTimeStamp uint64) (nvml.ValueType, []nvml.Sample, nvml.Return) {
+		return nvml.VALUE_TYPE_UNSIGNED_INT, nil, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	realMemoryInfo := nvmlDeviceGetMemoryInfo
+	defer func() { nvmlDeviceGetMemoryInfo = realMemoryInfo }()
+	nvmlDeviceGetMemoryInfo = func(device nvml.Device) (nvml.Memory, nvml.Return) {
+		return nvml.Memory{}, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	realTemperature := nvmlDeviceGetTemperature
+	defer func() { nvmlDeviceGetTemperature = realTemperature }()
+	nvmlDeviceGetTemperature = func(device nvml.Device, sensorType nvml.TemperatureSensors) (uint32, nvml.Return) {
+		return 0, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	realPowerUsage := nvmlDeviceGetPowerUsage
+	defer func() { nvmlDeviceGetPowerUsage = realPowerUsage }()
+	nvmlDeviceGetPowerUsage = func(device nvml.Device) (uint32, nvml.Return) { return 0, nvml.ERROR_NOT_SUPPORTED }
+
+	realPowerLimit := nvmlDeviceGetEnforcedPowerLimit
+	defer func() { nvmlDeviceGetEnforcedPowerLimit = realPowerLimit }()
+	nvmlDeviceGetEnforcedPowerLimit = func(device nvml.Device) (uint32, nvml.Return) { return 0, nvml.ERROR_NOT_SUPPORTED }
+
+	realFanSpeed := nvmlDeviceGetFanSpeed
+	defer func() { nvmlDeviceGetFanSpeed = realFanSpeed }()
+	nvmlDeviceGetFanSpeed = func(device nvml.Device) (uint32, nvml.Return) { return 50, nvml.SUCCESS }
+
+	realClockInfo := nvmlDeviceGetClockInfo
+	defer func() { nvmlDeviceGetClockInfo = realClockInfo }()
+	nvmlDeviceGetClockInfo = func(device nvml.Device, clockType nvml.ClockType) (uint32, nvml.Return) {
+		return 0, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	realPcieThroughput := nvmlDeviceGetPcieThroughput
+	defer func() { nvmlDeviceGetPcieThroughput = realPcieThroughput }()
+	nvmlDeviceGetPcieThroughput = func(device nvml.Device, counter nvml.PcieUtilCounter) (uint32, nvml.Return) {
+		return 0, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	realMemoryErrorCounter := nvmlDeviceGetMemoryErrorCounter
+	defer func() { nvmlDeviceGetMemoryErrorCounter = realMemoryErrorCounter }()
+	nvmlDeviceGetMemoryErrorCounter = func(
+		device nvml.Device, errorType nvml.MemoryErrorType, counterType nvml.EccCounterType, locationType nvml.MemoryLocation) (uint64, nvml.Return) {
+		return 0, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	realNvLinkState := nvmlDeviceGetNvLinkState
+	defer func() { nvmlDeviceGetNvLinkState = realNvLinkState }()
+	nvmlDeviceGetNvLinkState = func(device nvml.Device, link int) (nvml.EnableState, nvml.Return) {
+		return nvml.FEATURE_DISABLED, nvml.SUCCESS
 	}
 
-	warnings := 0
-	settings := componenttest.NewNopReceiverCreateSettings()
-	settings.Logger = zaptest.NewLogger(t, zaptest.WrapOptions(zap.Hooks(func(e zapcore.Entry) error {
-		if e.Level == zap.WarnLevel && strings.Contains(e.Message, "Unable to query") {
-			warnings = warnings + 1
+	cfg := createDefaultConfig().(*Config)
+	cfg.IncludeDevices = []string{"1", "0000:02:00.0"}
+	cfg.ExcludeDevices = []string{"GPU-1"}
+
+	scraper := newNvmlScraper(cfg, componenttest.NewNopReceiverCreateSettings())
+	require.NotNil(t, scraper)
+
+	err := scraper.start(context.Background(), componenttest.NewNopHost())
+	require.NoError(t, err)
+
+	metrics, err := scraper.scrape(context.Background())
+	require.NoError(t, err)
+
+	gpuNumbers := map[int64]bool{}
+	ms := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < ms.Len(); i++ {
+		dps := ms.At(i).Gauge().DataPoints()
+		for j := 0; j < dps.Len(); j++ {
+			if v, ok := dps.At(j).Attributes().Get("gpu_number"); ok {
+				gpuNumbers[v.IntVal()] = true
+			}
 		}
-		return nil
-	})))
+	}
 
-	scraper := newNvmlScraper(createDefaultConfig().(*Config), settings)
+	assert.Equal(t, map[int64]bool{2: true}, gpuNumbers,
+		"expected only device 2 (matched by PCI bus ID and not excluded by uuid) to survive filtering")
+}
+
+// TestScrapeWithNvLinkEnabled verifies that, when a link reports
+// FEATURE_ENABLED, throughput and per-error-type datapoints are emitted with
+// the link/direction/error_type/remote_uuid attributes consumers expect.
+func TestScrapeWithNvLinkEnabled(t *testing.T) {
+	realNvLinkState := nvmlDeviceGetNvLinkState
+	defer func() { nvmlDeviceGetNvLinkState = realNvLinkState }()
+	nvmlDeviceGetNvLinkState = func(device nvml.Device, link int) (nvml.EnableState, nvml.Return) {
+		if link == 0 {
+			return nvml.FEATURE_ENABLED, nvml.SUCCESS
+		}
+		return nvml.FEATURE_DISABLED, nvml.SUCCESS
+	}
+
+	realNvLinkUtilizationCounter := nvmlDeviceGetNvLinkUtilizationCounter
+	defer func() { nvmlDeviceGetNvLinkUtilizationCounter = realNvLinkUtilizationCounter }()
+	nvmlDeviceGetNvLinkUtilizationCounter = func(device nvml.Device, link, counter int) (uint64, uint64, nvml.Return) {
+		return 1024, 2048, nvml.SUCCESS
+	}
+
+	realNvLinkErrorCounter := nvmlDeviceGetNvLinkErrorCounter
+	defer func() { nvmlDeviceGetNvLinkErrorCounter = realNvLinkErrorCounter }()
+	nvmlDeviceGetNvLinkErrorCounter = func(device nvml.Device, link int, counter nvml.NvLinkErrorCounter) (uint64, nvml.Return) {
+		return 1, nvml.SUCCESS
+	}
+
+	realNvLinkRemotePciInfo := nvmlDeviceGetNvLinkRemotePciInfo
+	defer func() { nvmlDeviceGetNvLinkRemotePciInfo = realNvLinkRemotePciInfo }()
+	nvmlDeviceGetNvLinkRemotePciInfo = func(device nvml.Device, link int) (nvml.PciInfo, nvml.Return) {
+		return nvml.PciInfo{BusId: toBusIdArray("0000:01:00.0")}, nvml.SUCCESS
+	}
+
+	realPciInfo := nvmlDeviceGetPciInfo
+	defer func() { nvmlDeviceGetPciInfo = realPciInfo }()
+	nvmlDeviceGetPciInfo = func(device nvml.Device) (nvml.PciInfo, nvml.Return) {
+		return nvml.PciInfo{BusId: toBusIdArray("0000:01:00.0")}, nvml.SUCCESS
+	}
+
+	scraper := newNvmlScraper(createDefaultConfig().(*Config), componenttest.NewNopReceiverCreateSettings())
+	require.NotNil(t, scraper)
+
+	err := scraper.start(context.Background(), componenttest.NewNopHost())
+	require.NoError(t, err)
+
+	metrics, err := scraper.scrape(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, hasDatapointWithAttribute(metrics, "direction"), "expected an nvlink throughput datapoint")
+	assert.True(t, hasDatapointWithAttribute(metrics, "error_type"), "expected an nvlink error datapoint")
+	assert.True(t, hasDatapointWithAttribute(metrics, "remote_uuid"), "expected the nvlink remote device uuid to be resolved")
+}
+
+// TestScrapeResolvesNvLinkRemoteUUIDAcrossHigherIndex verifies that a device's
+// NVLink partner is resolved even when the partner has a higher device index,
+// guarding against a regression where busIDToUUID was populated in the same
+// pass that records metrics and so only contained devices already visited.
+func TestScrapeResolvesNvLinkRemoteUUIDAcrossHigherIndex(t *testing.T) {
+	realCount := nvmlDeviceGetCount
+	defer func() { nvmlDeviceGetCount = realCount }()
+	nvmlDeviceGetCount = func() (int, nvml.Return) { return 2, nvml.SUCCESS }
+
+	realHandleByIndex := nvmlDeviceGetHandleByIndex
+	defer func() { nvmlDeviceGetHandleByIndex = realHandleByIndex }()
+	nvmlDeviceGetHandleByIndex = func(index int) (nvml.Device, nvml.Return) {
+		return &fakeDevice{index: index}, nvml.SUCCESS
+	}
+
+	realUUID := nvmlDeviceGetUUID
+	defer func() { nvmlDeviceGetUUID = realUUID }()
+	nvmlDeviceGetUUID = func(device nvml.Device) (string, nvml.Return) {
+		return fmt.Sprintf("GPU-%d", device.(*fakeDevice).index), nvml.SUCCESS
+	}
+
+	realPciInfo := nvmlDeviceGetPciInfo
+	defer func() { nvmlDeviceGetPciInfo = realPciInfo }()
+	nvmlDeviceGetPciInfo = func(device nvml.Device) (nvml.PciInfo, nvml.Return) {
+		busID := fmt.Sprintf("00000000:0%d:00.0", device.(*fakeDevice).index)
+		return nvml.PciInfo{BusId: toBusIdArray(busID)}, nvml.SUCCESS
+	}
+
+	realName := nvmlDeviceGetName
+	defer func() { nvmlDeviceGetName = realName }()
+	nvmlDeviceGetName = func(device nvml.Device) (string, nvml.Return) { return "", nvml.SUCCESS }
+
+	realMigMode := nvmlDeviceGetMigMode
+	defer func() { nvmlDeviceGetMigMode = realMigMode }()
+	nvmlDeviceGetMigMode = func(device nvml.Device) (int, int, nvml.Return) {
+		return nvml.DEVICE_MIG_DISABLE, nvml.DEVICE_MIG_DISABLE, nvml.SUCCESS
+	}
+
+	realSamples := nvmlDeviceGetSamples
+	defer func() { nvmlDeviceGetSamples = realSamples }()
+	nvmlDeviceGetSamples = func(
+		device nvml.Device, _type nvml.SamplingType, lastSeenTimeStamp uint64) (nvml.ValueType, []nvml.Sample, nvml.Return) {
+		return nvml.VALUE_TYPE_UNSIGNED_INT, nil, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	realMemoryInfo := nvmlDeviceGetMemoryInfo
+	defer func() { nvmlDeviceGetMemoryInfo = realMemoryInfo }()
+	nvmlDeviceGetMemoryInfo = func(device nvml.Device) (nvml.Memory, nvml.Return) {
+		return nvml.Memory{}, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	realTemperature := nvmlDeviceGetTemperature
+	defer func() { nvmlDeviceGetTemperature = realTemperature }()
+	nvmlDeviceGetTemperature = func(device nvml.Device, sensorType nvml.TemperatureSensors) (uint32, nvml.Return) {
+		return 0, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	realPowerUsage := nvmlDeviceGetPowerUsage
+	defer func() { nvmlDeviceGetPowerUsage = realPowerUsage }()
+	nvmlDeviceGetPowerUsage = func(device nvml.Device) (uint32, nvml.Return) { return 0, nvml.ERROR_NOT_SUPPORTED }
+
+	realPowerLimit := nvmlDeviceGetEnforcedPowerLimit
+	defer func() { nvmlDeviceGetEnforcedPowerLimit = realPowerLimit }()
+	nvmlDeviceGetEnforcedPowerLimit = func(device nvml.Device) (uint32, nvml.Return) { return 0, nvml.ERROR_NOT_SUPPORTED }
+
+	realFanSpeed := nvmlDeviceGetFanSpeed
+	defer func() { nvmlDeviceGetFanSpeed = realFanSpeed }()
+	nvmlDeviceGetFanSpeed = func(device nvml.Device) (uint32, nvml.Return) { return 0, nvml.ERROR_NOT_SUPPORTED }
+
+	realClockInfo := nvmlDeviceGetClockInfo
+	defer func() { nvmlDeviceGetClockInfo = realClockInfo }()
+	nvmlDeviceGetClockInfo = func(device nvml.Device, clockType nvml.ClockType) (uint32, nvml.Return) {
+		return 0, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	realPcieThroughput := nvmlDeviceGetPcieThroughput
+	defer func() { nvmlDeviceGetPcieThroughput = realPcieThroughput }()
+	nvmlDeviceGetPcieThroughput = func(device nvml.Device, counter nvml.PcieUtilCounter) (uint32, nvml.Return) {
+		return 0, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	realMemoryErrorCounter := nvmlDeviceGetMemoryErrorCounter
+	defer func() { nvmlDeviceGetMemoryErrorCounter = realMemoryErrorCounter }()
+	nvmlDeviceGetMemoryErrorCounter = func(
+		device nvml.Device, errorType nvml.MemoryErrorType, counterType nvml.EccCounterType, locationType nvml.MemoryLocation) (uint64, nvml.Return) {
+		return 0, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	// Device 0 reports a single enabled link whose remote PCI info matches
+	// device 1 -- the partner NVML enumerates *after* the reporting device.
+	realNvLinkState := nvmlDeviceGetNvLinkState
+	defer func() { nvmlDeviceGetNvLinkState = realNvLinkState }()
+	nvmlDeviceGetNvLinkState = func(device nvml.Device, link int) (nvml.EnableState, nvml.Return) {
+		if device.(*fakeDevice).index == 0 && link == 0 {
+			return nvml.FEATURE_ENABLED, nvml.SUCCESS
+		}
+		return nvml.FEATURE_DISABLED, nvml.SUCCESS
+	}
+
+	realNvLinkUtilizationCounter := nvmlDeviceGetNvLinkUtilizationCounter
+	defer func() { nvmlDeviceGetNvLinkUtilizationCounter = realNvLinkUtilizationCounter }()
+	nvmlDeviceGetNvLinkUtilizationCounter = func(device nvml.Device, link, counter int) (uint64, uint64, nvml.Return) {
+		return 1024, 2048, nvml.SUCCESS
+	}
+
+	realNvLinkErrorCounter := nvmlDeviceGetNvLinkErrorCounter
+	defer func() { nvmlDeviceGetNvLinkErrorCounter = realNvLinkErrorCounter }()
+	nvmlDeviceGetNvLinkErrorCounter = func(device nvml.Device, link int, counter nvml.NvLinkErrorCounter) (uint64, nvml.Return) {
+		return 1, nvml.SUCCESS
+	}
+
+	realNvLinkRemotePciInfo := nvmlDeviceGetNvLinkRemotePciInfo
+	defer func() { nvmlDeviceGetNvLinkRemotePciInfo = realNvLinkRemotePciInfo }()
+	nvmlDeviceGetNvLinkRemotePciInfo = func(device nvml.Device, link int) (nvml.PciInfo, nvml.Return) {
+		return nvml.PciInfo{BusId: toBusIdArray("00000000:01:00.0")}, nvml.SUCCESS
+	}
+
+	scraper := newNvmlScraper(createDefaultConfig().(*Config), componenttest.NewNopReceiverCreateSettings())
 	require.NotNil(t, scraper)
 
 	err := scraper.start(context.Background(), componenttest.NewNopHost())
 	require.NoError(t, err)
 
-	for i := 0; i < maxWarningsForFailedDeviceMetricQuery+10; i++ {
-		scraper.scrape(context.Background())
+	metrics, err := scraper.scrape(context.Background())
+	require.NoError(t, err)
+
+	var gotRemoteUUID string
+	ms := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < ms.Len(); i++ {
+		if ms.At(i).Name() != "nvml.gpu.nvlink.throughput" {
+			continue
+		}
+		dps := ms.At(i).Gauge().DataPoints()
+		for j := 0; j < dps.Len(); j++ {
+			if v, ok := dps.At(j).Attributes().Get("remote_uuid"); ok {
+				gotRemoteUUID = v.StringVal()
+			}
+		}
+	}
+	assert.Equal(t, "GPU-1", gotRemoteUUID, "expected the link's remote uuid to resolve to device 1 even though it's enumerated after device 0")
+}
+
+func toBusIdArray(busID string) [32]int8 {
+	var arr [32]int8
+	for i := 0; i < len(busID) && i < len(arr); i++ {
+		arr[i] = int8(busID[i])
+	}
+	return arr
+}
+
+// TestScrapeEmitsWarningsUptoThreshold is parameterised over every NVML call
+// the scraper makes, since each one is throttled independently and a
+// regression in any single query's throttling key would otherwise go
+// unnoticed.
+// stubAllDeviceQueriesSuccessful stubs every NVML query the scrape path can
+// issue to succeed. TestScrapeEmitsWarningsUptoThreshold's subtests each fail
+// one query deliberately; without this baseline, any other family NVML
+// doesn't support on the host running the test (e.g. fan speed on
+// passively-cooled datacenter GPUs, or NVLink when no link is enabled) would
+// contribute its own "Unable to query" warnings and break the exact-count
+// assertion.
+func stubAllDeviceQueriesSuccessful() func() {
+	realSamples := nvmlDeviceGetSamples
+	nvmlDeviceGetSamples = func(device nvml.Device, _type nvml.SamplingType, lastSeenTimeStamp uint64) (nvml.ValueType, []nvml.Sample, nvml.Return) {
+		return nvml.VALUE_TYPE_UNSIGNED_INT, nil, nvml.SUCCESS
+	}
+
+	realMemoryInfo := nvmlDeviceGetMemoryInfo
+	nvmlDeviceGetMemoryInfo = func(device nvml.Device) (nvml.Memory, nvml.Return) {
+		return nvml.Memory{}, nvml.SUCCESS
+	}
+
+	realTemperature := nvmlDeviceGetTemperature
+	nvmlDeviceGetTemperature = func(device nvml.Device, sensorType nvml.TemperatureSensors) (uint32, nvml.Return) {
+		return 0, nvml.SUCCESS
+	}
+
+	realPowerUsage := nvmlDeviceGetPowerUsage
+	nvmlDeviceGetPowerUsage = func(device nvml.Device) (uint32, nvml.Return) { return 0, nvml.SUCCESS }
+
+	realPowerLimit := nvmlDeviceGetEnforcedPowerLimit
+	nvmlDeviceGetEnforcedPowerLimit = func(device nvml.Device) (uint32, nvml.Return) { return 0, nvml.SUCCESS }
+
+	realFanSpeed := nvmlDeviceGetFanSpeed
+	nvmlDeviceGetFanSpeed = func(device nvml.Device) (uint32, nvml.Return) { return 0, nvml.SUCCESS }
+
+	realClockInfo := nvmlDeviceGetClockInfo
+	nvmlDeviceGetClockInfo = func(device nvml.Device, clockType nvml.ClockType) (uint32, nvml.Return) {
+		return 0, nvml.SUCCESS
+	}
+
+	realPcieThroughput := nvmlDeviceGetPcieThroughput
+	nvmlDeviceGetPcieThroughput = func(device nvml.Device, counter nvml.PcieUtilCounter) (uint32, nvml.Return) {
+		return 0, nvml.SUCCESS
+	}
+
+	realMemoryErrorCounter := nvmlDeviceGetMemoryErrorCounter
+	nvmlDeviceGetMemoryErrorCounter = func(
+		device nvml.Device, errorType nvml.MemoryErrorType, counterType nvml.EccCounterType, locationType nvml.MemoryLocation) (uint64, nvml.Return) {
+		return 0, nvml.SUCCESS
+	}
+
+	realNvLinkState := nvmlDeviceGetNvLinkState
+	nvmlDeviceGetNvLinkState = func(device nvml.Device, link int) (nvml.EnableState, nvml.Return) {
+		return nvml.FEATURE_DISABLED, nvml.SUCCESS
+	}
+
+	realNvLinkUtilizationCounter := nvmlDeviceGetNvLinkUtilizationCounter
+	nvmlDeviceGetNvLinkUtilizationCounter = func(device nvml.Device, link, counter int) (uint64, uint64, nvml.Return) {
+		return 0, 0, nvml.SUCCESS
+	}
+
+	realNvLinkErrorCounter := nvmlDeviceGetNvLinkErrorCounter
+	nvmlDeviceGetNvLinkErrorCounter = func(device nvml.Device, link int, counter nvml.NvLinkErrorCounter) (uint64, nvml.Return) {
+		return 0, nvml.SUCCESS
+	}
+
+	realNvLinkRemotePciInfo := nvmlDeviceGetNvLinkRemotePciInfo
+	nvmlDeviceGetNvLinkRemotePciInfo = func(device nvml.Device, link int) (nvml.PciInfo, nvml.Return) {
+		return nvml.PciInfo{}, nvml.SUCCESS
+	}
+
+	return func() {
+		nvmlDeviceGetSamples = realSamples
+		nvmlDeviceGetMemoryInfo = realMemoryInfo
+		nvmlDeviceGetTemperature = realTemperature
+		nvmlDeviceGetPowerUsage = realPowerUsage
+		nvmlDeviceGetEnforcedPowerLimit = realPowerLimit
+		nvmlDeviceGetFanSpeed = realFanSpeed
+		nvmlDeviceGetClockInfo = realClockInfo
+		nvmlDeviceGetPcieThroughput = realPcieThroughput
+		nvmlDeviceGetMemoryErrorCounter = realMemoryErrorCounter
+		nvmlDeviceGetNvLinkState = realNvLinkState
+		nvmlDeviceGetNvLinkUtilizationCounter = realNvLinkUtilizationCounter
+		nvmlDeviceGetNvLinkErrorCounter = realNvLinkErrorCounter
+		nvmlDeviceGetNvLinkRemotePciInfo = realNvLinkRemotePciInfo
 	}
+}
+
+func TestScrapeEmitsWarningsUptoThreshold(t *testing.T) {
+	testCases := []struct {
+		name  string
+		setup func() func()
+	}{
+		{
+			name: "utilization",
+			setup: func() func() {
+				real := nvmlDeviceGetSamples
+				nvmlDeviceGetSamples = func(
+					device nvml.Device, _type nvml.SamplingType, LastSeenTimeStamp uint64) (nvml.ValueType, []nvml.Sample, nvml.Return) {
+					return nvml.VALUE_TYPE_SIGNED_LONG_LONG, nil, nvml.ERROR_NOT_SUPPORTED
+				}
+				return func() { nvmlDeviceGetSamples = real }
+			},
+		},
+		{
+			name: "memory_info",
+			setup: func() func() {
+				real := nvmlDeviceGetMemoryInfo
+				nvmlDeviceGetMemoryInfo = func(device nvml.Device) (nvml.Memory, nvml.Return) {
+					return nvml.Memory{}, nvml.ERROR_NOT_SUPPORTED
+				}
+				return func() { nvmlDeviceGetMemoryInfo = real }
+			},
+		},
+		{
+			name: "temperature",
+			setup: func() func() {
+				real := nvmlDeviceGetTemperature
+				nvmlDeviceGetTemperature = func(device nvml.Device, sensorType nvml.TemperatureSensors) (uint32, nvml.Return) {
+					return 0, nvml.ERROR_NOT_SUPPORTED
+				}
+				return func() { nvmlDeviceGetTemperature = real }
+			},
+		},
+		{
+			name: "power_usage",
+			setup: func() func() {
+				real := nvmlDeviceGetPowerUsage
+				nvmlDeviceGetPowerUsage = func(device nvml.Device) (uint32, nvml.Return) {
+					return 0, nvml.ERROR_NOT_SUPPORTED
+				}
+				return func() { nvmlDeviceGetPowerUsage = real }
+			},
+		},
+		{
+			name: "power_limit",
+			setup: func() func() {
+				real := nvmlDeviceGetEnforcedPowerLimit
+				nvmlDeviceGetEnforcedPowerLimit = func(device nvml.Device) (uint32, nvml.Return) {
+					return 0, nvml.ERROR_NOT_SUPPORTED
+				}
+				return func() { nvmlDeviceGetEnforcedPowerLimit = real }
+			},
+		},
+		{
+			name: "fan_speed",
+			setup: func() func() {
+				real := nvmlDeviceGetFanSpeed
+				nvmlDeviceGetFanSpeed = func(device nvml.Device) (uint32, nvml.Return) {
+					return 0, nvml.ERROR_NOT_SUPPORTED
+				}
+				return func() { nvmlDeviceGetFanSpeed = real }
+			},
+		},
+		{
+			name: "clock_info",
+			setup: func() func() {
+				real := nvmlDeviceGetClockInfo
+				nvmlDeviceGetClockInfo = func(device nvml.Device, clockType nvml.ClockType) (uint32, nvml.Return) {
+					return 0, nvml.ERROR_NOT_SUPPORTED
+				}
+				return func() { nvmlDeviceGetClockInfo = real }
+			},
+		},
+		{
+			name: "pcie_throughput",
+			setup: func() func() {
+				real := nvmlDeviceGetPcieThroughput
+				nvmlDeviceGetPcieThroughput = func(device nvml.Device, counter nvml.PcieUtilCounter) (uint32, nvml.Return) {
+					return 0, nvml.ERROR_NOT_SUPPORTED
+				}
+				return func() { nvmlDeviceGetPcieThroughput = real }
+			},
+		},
+		{
+			name: "ecc_errors",
+			setup: func() func() {
+				real := nvmlDeviceGetMemoryErrorCounter
+				nvmlDeviceGetMemoryErrorCounter = func(
+					device nvml.Device, errorType nvml.MemoryErrorType, counterType nvml.EccCounterType, locationType nvml.MemoryLocation) (uint64, nvml.Return) {
+					return 0, nvml.ERROR_NOT_SUPPORTED
+				}
+				return func() { nvmlDeviceGetMemoryErrorCounter = real }
+			},
+		},
+		{
+			name: "nvlink_utilization",
+			setup: func() func() {
+				// stubAllDeviceQueriesSuccessful already leaves NvLinkState
+				// disabled and every NVLink query succeeding; enable link 0
+				// and fail only the utilization counter under test.
+				realState := nvmlDeviceGetNvLinkState
+				nvmlDeviceGetNvLinkState = func(device nvml.Device, link int) (nvml.EnableState, nvml.Return) {
+					if link == 0 {
+						return nvml.FEATURE_ENABLED, nvml.SUCCESS
+					}
+					return nvml.FEATURE_DISABLED, nvml.SUCCESS
+				}
+
+				real := nvmlDeviceGetNvLinkUtilizationCounter
+				nvmlDeviceGetNvLinkUtilizationCounter = func(device nvml.Device, link, counter int) (uint64, uint64, nvml.Return) {
+					return 0, 0, nvml.ERROR_NOT_SUPPORTED
+				}
+				return func() {
+					nvmlDeviceGetNvLinkState = realState
+					nvmlDeviceGetNvLinkUtilizationCounter = real
+				}
+			},
+		},
+		{
+			name: "nvlink_errors",
+			setup: func() func() {
+				// As above, but failing only the error counter under test.
+				realState := nvmlDeviceGetNvLinkState
+				nvmlDeviceGetNvLinkState = func(device nvml.Device, link int) (nvml.EnableState, nvml.Return) {
+					if link == 0 {
+						return nvml.FEATURE_ENABLED, nvml.SUCCESS
+					}
+					return nvml.FEATURE_DISABLED, nvml.SUCCESS
+				}
+
+				real := nvmlDeviceGetNvLinkErrorCounter
+				nvmlDeviceGetNvLinkErrorCounter = func(device nvml.Device, link int, counter nvml.NvLinkErrorCounter) (uint64, nvml.Return) {
+					if counter == nvml.NVLINK_ERROR_DL_CRC_DATA {
+						return 0, nvml.ERROR_NOT_SUPPORTED
+					}
+					return 0, nvml.SUCCESS
+				}
+				return func() {
+					nvmlDeviceGetNvLinkState = realState
+					nvmlDeviceGetNvLinkErrorCounter = real
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			teardownBaseline := stubAllDeviceQueriesSuccessful()
+			defer teardownBaseline()
+
+			teardown := tc.setup()
+			defer teardown()
 
-	require.Equal(t, warnings, maxWarningsForFailedDeviceMetricQuery)
+			warnings := 0
+			settings := componenttest.NewNopReceiverCreateSettings()
+			settings.Logger = zaptest.NewLogger(t, zaptest.WrapOptions(zap.Hooks(func(e zapcore.Entry) error {
+				if e.Level == zap.WarnLevel && strings.Contains(e.Message, "Unable to query") {
+					warnings = warnings + 1
+				}
+				return nil
+			})))
+
+			scraper := newNvmlScraper(createDefaultConfig().(*Config), settings)
+			require.NotNil(t, scraper)
+
+			err := scraper.start(context.Background(), componenttest.NewNopHost())
+			require.NoError(t, err)
+
+			for i := 0; i < maxWarningsForFailedDeviceMetricQuery+10; i++ {
+				scraper.scrape(context.Background())
+			}
+
+			require.Equal(t, maxWarningsForFailedDeviceMetricQuery, warnings)
+		})
+	}
 }
 
-func validateScraperResult(t *testing.T, metrics pmetric.Metrics, expected_metrics []string) {
+// validateScraperResult asserts that every metric in expected_metrics is
+// present with its expected datapoint shape. optional_metrics may additionally
+// be present (e.g. families like fan speed that some real GPUs don't support)
+// but aren't required; any other metric name fails the test.
+func validateScraperResult(t *testing.T, metrics pmetric.Metrics, expected_metrics []string, optional_metrics ...string) {
 	expected_datapoints := map[string]int{
-		"nvml.gpu.utilization":       1,
-		"nvml.gpu.memory.bytes_used": 2,
+		"nvml.gpu.utilization":            1,
+		"nvml.gpu.memory.bytes_used":      2,
+		"nvml.gpu.temperature":            1,
+		"nvml.gpu.power.usage_milliwatts": 1,
+		"nvml.gpu.power.limit":            1,
+		"nvml.gpu.fan.speed":              1,
+		"nvml.gpu.clock.frequency":        4,
+		"nvml.gpu.pcie.throughput":        2,
+		"nvml.gpu.ecc.errors":             8,
 	}
 
-	count := 0
+	allowed := map[string]bool{}
 	for _, s := range expected_metrics {
-		count += expected_datapoints[s]
+		allowed[s] = true
+	}
+	for _, s := range optional_metrics {
+		allowed[s] = true
 	}
-
-	assert.Equal(t, metrics.MetricCount(), len(expected_metrics))
-	assert.Equal(t, metrics.DataPointCount(), count)
 
 	ilms := metrics.ResourceMetrics().At(0).ScopeMetrics()
 	require.Equal(t, 1, ilms.Len())
 
 	ms := ilms.At(0).Metrics()
+
+	present := map[string]bool{}
+	for i := 0; i < ms.Len(); i++ {
+		present[ms.At(i).Name()] = true
+		assert.True(t, allowed[ms.At(i).Name()], "unexpected metric %s", ms.At(i).Name())
+	}
+	for _, s := range expected_metrics {
+		assert.True(t, present[s], "expected metric %s to be present", s)
+	}
+
 	for i := 0; i < ms.Len(); i++ {
 		m := ms.At(i)
 		dps := m.Gauge().DataPoints()
@@ -143,6 +928,42 @@ func validateScraperResult(t *testing.T, metrics pmetric.Metrics, expected_metri
 			for j := 0; j < dps.Len(); j++ {
 				assert.Regexp(t, ".*memory_state:.*", dps.At(j).Attributes().AsRaw())
 			}
+		case "nvml.gpu.temperature":
+			assert.Equal(t, expected_datapoints["nvml.gpu.temperature"], dps.Len())
+		case "nvml.gpu.power.usage_milliwatts":
+			assert.Equal(t, expected_datapoints["nvml.gpu.power.usage_milliwatts"], dps.Len())
+		case "nvml.gpu.power.limit":
+			assert.Equal(t, expected_datapoints["nvml.gpu.power.limit"], dps.Len())
+		case "nvml.gpu.fan.speed":
+			assert.Equal(t, expected_datapoints["nvml.gpu.fan.speed"], dps.Len())
+		case "nvml.gpu.clock.frequency":
+			assert.Equal(t, expected_datapoints["nvml.gpu.clock.frequency"], dps.Len())
+			for j := 0; j < dps.Len(); j++ {
+				assert.Regexp(t, ".*clock_type:.*", dps.At(j).Attributes().AsRaw())
+			}
+		case "nvml.gpu.pcie.throughput":
+			assert.Equal(t, expected_datapoints["nvml.gpu.pcie.throughput"], dps.Len())
+			for j := 0; j < dps.Len(); j++ {
+				assert.Regexp(t, ".*direction:.*", dps.At(j).Attributes().AsRaw())
+			}
+		case "nvml.gpu.ecc.errors":
+			assert.Equal(t, expected_datapoints["nvml.gpu.ecc.errors"], dps.Len())
+			for j := 0; j < dps.Len(); j++ {
+				assert.Regexp(t, ".*type:.*", dps.At(j).Attributes().AsRaw())
+				assert.Regexp(t, ".*location:.*", dps.At(j).Attributes().AsRaw())
+			}
+		case "nvml.gpu.nvlink.throughput":
+			// Datapoint count depends on how many links the host has enabled,
+			// so only the attribute shape is checked here.
+			for j := 0; j < dps.Len(); j++ {
+				assert.Regexp(t, ".*link:.*", dps.At(j).Attributes().AsRaw())
+				assert.Regexp(t, ".*direction:.*", dps.At(j).Attributes().AsRaw())
+			}
+		case "nvml.gpu.nvlink.errors":
+			for j := 0; j < dps.Len(); j++ {
+				assert.Regexp(t, ".*link:.*", dps.At(j).Attributes().AsRaw())
+				assert.Regexp(t, ".*error_type:.*", dps.At(j).Attributes().AsRaw())
+			}
 		default:
 			t.Errorf("Unexpected metric %s", m.Name())
 		}
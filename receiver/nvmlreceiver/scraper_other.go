@@ -0,0 +1,42 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !gpu
+// +build !gpu
+
+package nvmlreceiver
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// nvmlScraper is a no-op stand-in used on builds without the gpu tag, where
+// the cgo bindings required to talk to NVML are not compiled in.
+type nvmlScraper struct{}
+
+func newNvmlScraper(_ *Config, _ component.ReceiverCreateSettings) *nvmlScraper {
+	return &nvmlScraper{}
+}
+
+func (s *nvmlScraper) start(_ context.Context, _ component.Host) error {
+	return errors.New("the nvml receiver requires the collector to be built with the gpu build tag")
+}
+
+func (s *nvmlScraper) scrape(_ context.Context) (pmetric.Metrics, error) {
+	return pmetric.NewMetrics(), nil
+}